@@ -0,0 +1,134 @@
+package metadata
+
+import (
+	"context"
+	"sync"
+)
+
+// ContentConfigResult is the outcome of one id within a GetConfigs batch.
+type ContentConfigResult struct {
+	Config ContentConfig
+	Code   int
+	Err    error
+}
+
+// EncryptionRequest identifies a single content id/bitrate pair within a
+// GetEncryptionConfigs batch.
+type EncryptionRequest struct {
+	Id      string
+	Bitrate string
+}
+
+// ContentEncryptionConfigResult is the outcome of one EncryptionRequest
+// within a GetEncryptionConfigs batch.
+type ContentEncryptionConfigResult struct {
+	Config ContentEncryptionConfig
+	Code   int
+	Err    error
+}
+
+// GetConfigs retrieves the content config for every id in ids. Requests are
+// fanned out across a bounded worker pool sized by MaxConcurrentRequests,
+// duplicate ids are only fetched once, and the cache (with its singleflight
+// coalescing) is consulted the same way GetConfig would. A failure fetching
+// one id is recorded in that id's result rather than failing the batch.
+func (rc *RemoteContent) GetConfigs(ids []string) (map[string]ContentConfigResult, error) {
+	return rc.GetConfigsCtx(context.Background(), ids)
+}
+
+// GetConfigsCtx is GetConfigs with a caller-supplied context, used to bound
+// every request in the batch with a deadline or cancellation.
+func (rc *RemoteContent) GetConfigsCtx(ctx context.Context, ids []string) (map[string]ContentConfigResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	results := make(map[string]ContentConfigResult, len(unique))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, rc.MaxConcurrentRequests)
+
+	for _, id := range unique {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var cfg ContentConfig
+			code, err := rc.GetConfigCtx(ctx, id, &cfg)
+
+			mu.Lock()
+			results[id] = ContentConfigResult{Config: cfg, Code: code, Err: err}
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// GetEncryptionConfigs retrieves the content encryption config for every
+// request in reqs. Requests are fanned out across a bounded worker pool
+// sized by MaxConcurrentRequests, duplicate (id, bitrate) pairs are only
+// fetched once, and the cache (with its singleflight coalescing) is
+// consulted the same way GetEncryptionConfig would. A failure fetching one
+// request is recorded in that request's result rather than failing the
+// batch. Results are keyed the same way as the cache: id + bitrate.
+func (rc *RemoteContent) GetEncryptionConfigs(reqs []EncryptionRequest) (map[string]ContentEncryptionConfigResult, error) {
+	return rc.GetEncryptionConfigsCtx(context.Background(), reqs)
+}
+
+// GetEncryptionConfigsCtx is GetEncryptionConfigs with a caller-supplied
+// context, used to bound every request in the batch with a deadline or
+// cancellation.
+func (rc *RemoteContent) GetEncryptionConfigsCtx(ctx context.Context, reqs []EncryptionRequest) (map[string]ContentEncryptionConfigResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	unique := make([]EncryptionRequest, 0, len(reqs))
+	seen := make(map[string]bool, len(reqs))
+	for _, req := range reqs {
+		key := req.Id + req.Bitrate
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, req)
+	}
+
+	results := make(map[string]ContentEncryptionConfigResult, len(unique))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, rc.MaxConcurrentRequests)
+
+	for _, req := range unique {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(req EncryptionRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var cfg ContentEncryptionConfig
+			code, err := rc.GetEncryptionConfigCtx(ctx, req.Id, req.Bitrate, &cfg)
+
+			mu.Lock()
+			results[req.Id+req.Bitrate] = ContentEncryptionConfigResult{Config: cfg, Code: code, Err: err}
+			mu.Unlock()
+		}(req)
+	}
+	wg.Wait()
+
+	return results, nil
+}
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestGetConfigsDedupesAndMergesResults(t *testing.T) {
+	var requests int32
+
+	r := mux.NewRouter()
+	r.HandleFunc("/contents/{contentId}", func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		contentId := mux.Vars(req)["contentId"]
+		if contentId == "bad-id" {
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte("Content doesn't exist"))
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(fmt.Sprintf(`{"uuid":%q}`, contentId)))
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	contentManager, err := NewContentManager(ts.URL, ts.Client(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{"good-id", "good-id", "bad-id"}
+	results, err := contentManager.GetConfigs(ids)
+	if err != nil {
+		t.Fatalf("unexpected batch error: %s", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 distinct results, got %d", len(results))
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("expected duplicate ids to be fetched once, upstream saw %d requests", got)
+	}
+
+	good := results["good-id"]
+	if good.Code != http.StatusOK || good.Err != nil {
+		t.Errorf("unexpected result for good-id: %+v", good)
+	}
+	if good.Config.Uuid != "good-id" {
+		t.Errorf("expected decoded config for good-id, got %+v", good.Config)
+	}
+
+	bad := results["bad-id"]
+	if bad.Code != http.StatusBadRequest || bad.Err == nil {
+		t.Errorf("expected bad-id to fail without failing the batch, got %+v", bad)
+	}
+}
+
+func TestGetEncryptionConfigsDedupesAndMergesResults(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/contents/{contentId}/encryption-percentage", func(rw http.ResponseWriter, req *http.Request) {
+		bitrate := req.URL.Query().Get("bitrate")
+		if bitrate == "0" {
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte("Bitrates list is not compliant"))
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"sessionBasedEncryptionPercentage":20,"vivEncryptionPercentage":20}`))
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	contentManager, err := NewContentManager(ts.URL, ts.Client(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := []EncryptionRequest{
+		{Id: "content-id", Bitrate: "1080"},
+		{Id: "content-id", Bitrate: "0"},
+	}
+	results, err := contentManager.GetEncryptionConfigs(reqs)
+	if err != nil {
+		t.Fatalf("unexpected batch error: %s", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 distinct results, got %d", len(results))
+	}
+
+	ok := results["content-id1080"]
+	if ok.Code != http.StatusOK || ok.Err != nil {
+		t.Errorf("unexpected result for 1080 bitrate: %+v", ok)
+	}
+
+	failed := results["content-id0"]
+	if failed.Code != http.StatusBadRequest || failed.Err == nil {
+		t.Errorf("expected the invalid bitrate to fail without failing the batch, got %+v", failed)
+	}
+}
+
+func TestGetConfigsRespectsMaxConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	r := mux.NewRouter()
+	r.HandleFunc("/contents/{contentId}", func(rw http.ResponseWriter, req *http.Request) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if cur <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, cur) {
+				break
+			}
+		}
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"uuid":"x"}`))
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	contentManager, err := NewContentManagerWithOptions(ts.URL, ts.Client(), Options{MaxConcurrentRequests: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{"a", "b", "c", "d", "e", "f"}
+	if _, err := contentManager.GetConfigs(ids); err != nil {
+		t.Fatalf("unexpected batch error: %s", err)
+	}
+
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("expected at most 2 concurrent requests, saw %d", got)
+	}
+}
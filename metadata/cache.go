@@ -0,0 +1,107 @@
+package metadata
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// cacheEntry is the value held by each element of the LRU list.
+type cacheEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// contentCache is a bounded, TTL-aware LRU cache safe for concurrent
+// readers/writers. Concurrent misses for the same key are coalesced
+// through a singleflight.Group so that a burst of lookups for the same
+// key results in exactly one upstream fetch.
+type contentCache struct {
+	mu       sync.RWMutex
+	ll       *list.List
+	items    map[string]*list.Element
+	capacity int
+	ttl      time.Duration
+	group    singleflight.Group
+}
+
+// newContentCache returns a contentCache bounded to capacity entries, each
+// expiring ttl after it is set. A capacity <= 0 disables eviction.
+func newContentCache(capacity int, ttl time.Duration) *contentCache {
+	return &contentCache{
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		capacity: capacity,
+		ttl:      ttl,
+	}
+}
+
+// get returns the cached value for key, if present and not yet expired.
+func (c *contentCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(elem)
+	return entry.value, true
+}
+
+// set stores value under key, refreshing its TTL and evicting the least
+// recently used entry if the cache is over capacity.
+func (c *contentCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if elem, ok := c.items[key]; ok {
+		c.ll.MoveToFront(elem)
+		entry := elem.Value.(*cacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		return
+	}
+
+	elem := c.ll.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt})
+	c.items[key] = elem
+
+	if c.capacity > 0 && c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// len reports the number of entries held, including expired ones not yet
+// reaped by a get call.
+func (c *contentCache) len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ll.Len()
+}
+
+// removeElement evicts elem from the cache. Callers must hold c.mu.
+func (c *contentCache) removeElement(elem *list.Element) {
+	if elem == nil {
+		return
+	}
+	c.ll.Remove(elem)
+	entry := elem.Value.(*cacheEntry)
+	delete(c.items, entry.key)
+}
+
+// do coalesces concurrent calls sharing the same key into a single
+// invocation of fn, so that N in-flight misses trigger one fetch.
+func (c *contentCache) do(key string, fn func() (interface{}, error)) (interface{}, error, bool) {
+	return c.group.Do(key, fn)
+}
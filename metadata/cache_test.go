@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestContentCacheGetSet(t *testing.T) {
+	c := newContentCache(2, time.Minute)
+
+	if _, ok := c.get("a"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	c.set("a", "value-a")
+	val, ok := c.get("a")
+	if !ok || val != "value-a" {
+		t.Fatalf("expected hit with 'value-a', got %v, %v", val, ok)
+	}
+}
+
+func TestContentCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newContentCache(2, time.Minute)
+
+	c.set("a", "1")
+	c.set("b", "2")
+	// Touch "a" so "b" becomes the least recently used entry.
+	c.get("a")
+	c.set("c", "3")
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("expected 'b' to have been evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected 'a' to still be cached")
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Fatal("expected 'c' to be cached")
+	}
+	if got := c.len(); got != 2 {
+		t.Fatalf("expected cache len 2, got %d", got)
+	}
+}
+
+func TestContentCacheExpiresEntries(t *testing.T) {
+	c := newContentCache(10, time.Millisecond)
+
+	c.set("a", "1")
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.get("a"); ok {
+		t.Fatal("expected entry to have expired")
+	}
+}
+
+func TestContentCacheDoCoalescesConcurrentMisses(t *testing.T) {
+	c := newContentCache(10, time.Minute)
+
+	var calls int32
+	const workers = 20
+
+	done := make(chan struct{}, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			_, _, _ = c.do("shared-key", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+				return "result", nil
+			})
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < workers; i++ {
+		<-done
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected exactly 1 call, got %d", got)
+	}
+}
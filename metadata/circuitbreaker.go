@@ -0,0 +1,82 @@
+package metadata
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a circuit breaker is open and short
+// circuiting calls to a failing endpoint.
+var ErrCircuitOpen = errors.New("metadata: circuit breaker open for endpoint")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker is a minimal consecutive-failure breaker: it opens after
+// failureThreshold consecutive failures, stays open for cooldown, then lets
+// a single half-open probe through to decide whether to close again.
+type circuitBreaker struct {
+	mu                  sync.Mutex
+	failureThreshold    int
+	cooldown            time.Duration
+	state               breakerState
+	consecutiveFailures int
+	openedAt            time.Time
+}
+
+func newCircuitBreaker(failureThreshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+	}
+}
+
+// allow reports whether a call should be let through. It transitions an open
+// breaker to half-open once the cooldown has elapsed, admitting exactly the
+// caller that observes the transition as the probe.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// A probe is already in flight; block others until it resolves.
+		return false
+	default:
+		return true
+	}
+}
+
+// recordSuccess closes the breaker and resets its failure count.
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures = 0
+	b.state = breakerClosed
+}
+
+// recordFailure increments the failure count and opens the breaker once the
+// threshold is reached, or immediately if a half-open probe failed.
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures++
+	if b.state == breakerHalfOpen || b.consecutiveFailures >= b.failureThreshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
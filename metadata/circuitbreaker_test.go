@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b := newCircuitBreaker(3, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if !b.allow() {
+			t.Fatalf("expected breaker to allow call %d", i)
+		}
+		b.recordFailure()
+	}
+	if !b.allow() {
+		t.Fatal("expected breaker to still be closed before the threshold")
+	}
+	b.recordFailure()
+
+	if b.allow() {
+		t.Fatal("expected breaker to be open after reaching the failure threshold")
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	b := newCircuitBreaker(1, 10*time.Millisecond)
+
+	b.allow()
+	b.recordFailure()
+	if b.allow() {
+		t.Fatal("expected breaker to be open immediately after tripping")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("expected a half-open probe to be allowed after the cooldown")
+	}
+	if b.allow() {
+		t.Fatal("expected only a single half-open probe to be admitted")
+	}
+}
+
+func TestCircuitBreakerClosesOnSuccess(t *testing.T) {
+	b := newCircuitBreaker(1, 10*time.Millisecond)
+
+	b.allow()
+	b.recordFailure()
+	time.Sleep(20 * time.Millisecond)
+
+	if !b.allow() {
+		t.Fatal("expected a half-open probe to be allowed")
+	}
+	b.recordSuccess()
+
+	if !b.allow() {
+		t.Fatal("expected breaker to be closed after a successful probe")
+	}
+}
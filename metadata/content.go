@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -41,6 +43,7 @@ type ContentConfig struct {
 	CreatedAt                        time.Time `json:"createdAt"`
 	UpdatedAt                        time.Time `json:"updatedAt"`
 	DeletedAt                        time.Time `json:"deletedAt"`
+	RawData                          string    `json:"-"`
 }
 
 // RemoteContent manages content metadata by making http requests to
@@ -50,38 +53,126 @@ type RemoteContent struct {
 	ServiceUrl                   *url.URL
 	Client                       *http.Client
 	Logger                       zerolog.Logger
-	ContentConfigCache           map[string]*ContentConfig
-	ContentEncryptionConfigCache map[string]*ContentEncryptionConfig
+	ContentConfigCache           *contentCache
+	ContentEncryptionConfigCache *contentCache
 	CacheEnabled                 bool
+
+	// RetryInitialInterval, RetryMaxInterval and RetryMaxElapsedTime tune
+	// the backoff applied to retried requests. Set via Options.
+	RetryInitialInterval time.Duration
+	RetryMaxInterval     time.Duration
+	RetryMaxElapsedTime  time.Duration
+
+	// configBreaker and encryptionConfigBreaker guard the two upstream
+	// endpoints independently, so a failing encryption-percentage lookup
+	// doesn't short circuit content config lookups and vice versa.
+	configBreaker           *circuitBreaker
+	encryptionConfigBreaker *circuitBreaker
+
+	// TrustedKeys maps key id to public key for verifying the
+	// X-Vividas-Signature header. When empty, responses are accepted
+	// unverified.
+	TrustedKeys map[string]ed25519.PublicKey
+
+	// Metrics receives instrumentation events for every cache lookup,
+	// HTTP round trip, retry, and call short circuited by an open circuit
+	// breaker.
+	Metrics Collector
+
+	// configStats and encryptionConfigStats back Stats() and are updated
+	// alongside Metrics so callers who don't run Prometheus still get a
+	// snapshot of activity.
+	configStats           *endpointStatTracker
+	encryptionConfigStats *endpointStatTracker
+
+	// MaxConcurrentRequests bounds how many upstream requests a batch call
+	// (GetConfigs, GetEncryptionConfigs) issues at once.
+	MaxConcurrentRequests int
+
+	// recorder writes a reproducer file for every outbound HTTP call when
+	// Options.RecordDir was set.
+	recorder *requestRecorder
+}
+
+// Endpoint labels used to tag metrics and stats.
+const (
+	endpointConfig           = "config"
+	endpointEncryptionConfig = "encryption_config"
+)
+
+// configFetchResult carries the outcome of a single upstream content config
+// fetch through the singleflight group to every caller it was coalesced for.
+type configFetchResult struct {
+	config *ContentConfig
+	code   int
 }
 
 // GetConfig retrieves the metadata configuration belongs to a content id
 // and stores the result in the value pointed to by v.
 func (rc *RemoteContent) GetConfig(id string, v *ContentConfig) (int, error) {
+	return rc.GetConfigCtx(context.Background(), id, v)
+}
+
+// GetConfigCtx is GetConfig with a caller-supplied context, used to bound
+// the request (including its retries) with a deadline or cancellation.
+func (rc *RemoteContent) GetConfigCtx(ctx context.Context, id string, v *ContentConfig) (int, error) {
 	cacheKey := id
 	if rc.CacheEnabled {
-		val, ok := rc.ContentConfigCache[cacheKey]
-		if ok {
+		if val, ok := rc.ContentConfigCache.get(cacheKey); ok {
+			rc.Metrics.IncCacheHit(endpointConfig)
+			rc.configStats.recordCacheHit()
 			rc.Logger.Info().Msg("content config retrieved from cache")
-			v = val
+			*v = *val.(*ContentConfig)
 			rc.Logger.Debug().Msgf("content config:%+v", *v)
 			return http.StatusOK, nil
 		}
+		rc.Metrics.IncCacheMiss(endpointConfig)
+		rc.configStats.recordCacheMiss()
 	}
 
+	res, err, shared := rc.ContentConfigCache.do(cacheKey, func() (interface{}, error) {
+		cfg := &ContentConfig{}
+		code, ferr := rc.withRetryAndBreaker(ctx, endpointConfig, rc.configBreaker, rc.configStats, func() (int, time.Duration, error) {
+			return rc.fetchConfig(ctx, id, cfg)
+		})
+		if ferr != nil {
+			return configFetchResult{code: code}, ferr
+		}
+
+		if rc.CacheEnabled {
+			rc.ContentConfigCache.set(cacheKey, cfg)
+			rc.Metrics.SetCacheSize(endpointConfig, rc.ContentConfigCache.len())
+		}
+		return configFetchResult{config: cfg, code: code}, nil
+	})
+	if shared {
+		rc.Logger.Debug().Msgf("content config fetch for %s coalesced with an in-flight request", id)
+	}
+
+	result := res.(configFetchResult)
+	if err != nil {
+		return result.code, err
+	}
+	*v = *result.config
+	return result.code, nil
+}
+
+// fetchConfig performs a single HTTP round trip for a content config and
+// decodes it into v, reporting any Retry-After delay the upstream requested.
+func (rc *RemoteContent) fetchConfig(ctx context.Context, id string, v *ContentConfig) (int, time.Duration, error) {
 	endpoint := rc.ServiceUrl.JoinPath("/contents/" + id).String()
 
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		rc.Logger.Error().Msg(err.Error())
-		return http.StatusInternalServerError, err
+		return http.StatusInternalServerError, 0, err
 	}
 
 	rc.Logger.Debug().Msgf("requesting content config from %s", endpoint)
 	resp, err := rc.Client.Do(req)
 	if err != nil {
 		rc.Logger.Error().Msg(err.Error())
-		return http.StatusInternalServerError, err
+		return 0, 0, &netError{err}
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -94,61 +185,129 @@ func (rc *RemoteContent) GetConfig(id string, v *ContentConfig) (int, error) {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
 			rc.Logger.Error().Msg(err.Error())
-			return http.StatusInternalServerError, err
+			return http.StatusInternalServerError, 0, err
+		}
+		if err := rc.recorder.record(req, resp.StatusCode, body, nil); err != nil {
+			rc.Logger.Error().Msg(err.Error())
 		}
 		msg := string(body)
 		rc.Logger.Debug().Msgf("%d - %s", resp.StatusCode, msg)
-		return resp.StatusCode, fmt.Errorf("%s", msg)
+		return resp.StatusCode, retryAfter(resp.Header), fmt.Errorf("%s", msg)
 	}
 
-	rc.Logger.Info().Msg("decoding content config...")
-	err = json.NewDecoder(resp.Body).Decode(v)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		rc.Logger.Error().Msg(err.Error())
-		return http.StatusInternalServerError, err
+		return http.StatusInternalServerError, 0, err
 	}
+
+	if len(rc.TrustedKeys) > 0 {
+		if err := verifySignature(rc.TrustedKeys, resp.Header, body); err != nil {
+			rc.Logger.Error().Msg(err.Error())
+			if recErr := rc.recorder.record(req, resp.StatusCode, body, err); recErr != nil {
+				rc.Logger.Error().Msg(recErr.Error())
+			}
+			return http.StatusInternalServerError, 0, err
+		}
+	}
+
+	rc.Logger.Info().Msg("decoding content config...")
+	decodeErr := json.Unmarshal(body, v)
+	if err := rc.recorder.record(req, resp.StatusCode, body, decodeErr); err != nil {
+		rc.Logger.Error().Msg(err.Error())
+	}
+	if decodeErr != nil {
+		rc.Logger.Error().Msg(decodeErr.Error())
+		return http.StatusInternalServerError, 0, decodeErr
+	}
+	v.RawData = string(body)
 	rc.Logger.Info().Msg("content config successfully decoded")
 	rc.Logger.Debug().Msgf("content config:%+v", *v)
 
-	if rc.CacheEnabled {
-		rc.ContentConfigCache[cacheKey] = v
-	}
+	return resp.StatusCode, 0, nil
+}
 
-	return resp.StatusCode, nil
+// encryptionConfigFetchResult carries the outcome of a single upstream
+// encryption config fetch through the singleflight group to every caller it
+// was coalesced for.
+type encryptionConfigFetchResult struct {
+	config *ContentEncryptionConfig
+	code   int
 }
 
 // GetEncryptionConfig retrieves the metadata encryption configuration
 // belongs to a content id and associated to a bitrate value.
 // It stores the result in the value pointed to by v.
 func (rc *RemoteContent) GetEncryptionConfig(id string, bitrate string, v *ContentEncryptionConfig) (int, error) {
+	return rc.GetEncryptionConfigCtx(context.Background(), id, bitrate, v)
+}
+
+// GetEncryptionConfigCtx is GetEncryptionConfig with a caller-supplied
+// context, used to bound the request (including its retries) with a
+// deadline or cancellation.
+func (rc *RemoteContent) GetEncryptionConfigCtx(ctx context.Context, id string, bitrate string, v *ContentEncryptionConfig) (int, error) {
 	cacheKey := id + bitrate
 	if rc.CacheEnabled {
-		val, ok := rc.ContentEncryptionConfigCache[cacheKey]
-		if ok {
+		if val, ok := rc.ContentEncryptionConfigCache.get(cacheKey); ok {
+			rc.Metrics.IncCacheHit(endpointEncryptionConfig)
+			rc.encryptionConfigStats.recordCacheHit()
 			rc.Logger.Info().Msg("content encryption config retrieved from cache")
-			v = val
+			*v = *val.(*ContentEncryptionConfig)
 			rc.Logger.Debug().Msgf("content encryption config:%+v", *v)
 			return http.StatusOK, nil
 		}
+		rc.Metrics.IncCacheMiss(endpointEncryptionConfig)
+		rc.encryptionConfigStats.recordCacheMiss()
+	}
+
+	res, err, shared := rc.ContentEncryptionConfigCache.do(cacheKey, func() (interface{}, error) {
+		cfg := &ContentEncryptionConfig{}
+		code, ferr := rc.withRetryAndBreaker(ctx, endpointEncryptionConfig, rc.encryptionConfigBreaker, rc.encryptionConfigStats, func() (int, time.Duration, error) {
+			return rc.fetchEncryptionConfig(ctx, id, bitrate, cfg)
+		})
+		if ferr != nil {
+			return encryptionConfigFetchResult{code: code}, ferr
+		}
+
+		if rc.CacheEnabled {
+			rc.ContentEncryptionConfigCache.set(cacheKey, cfg)
+			rc.Metrics.SetCacheSize(endpointEncryptionConfig, rc.ContentEncryptionConfigCache.len())
+		}
+		return encryptionConfigFetchResult{config: cfg, code: code}, nil
+	})
+	if shared {
+		rc.Logger.Debug().Msgf("content encryption config fetch for %s/%s coalesced with an in-flight request", id, bitrate)
 	}
 
+	result := res.(encryptionConfigFetchResult)
+	if err != nil {
+		return result.code, err
+	}
+	*v = *result.config
+	return result.code, nil
+}
+
+// fetchEncryptionConfig performs a single HTTP round trip for a content
+// encryption config and decodes it into v, reporting any Retry-After delay
+// the upstream requested.
+func (rc *RemoteContent) fetchEncryptionConfig(ctx context.Context, id string, bitrate string, v *ContentEncryptionConfig) (int, time.Duration, error) {
 	encryptionConfigUrl := rc.ServiceUrl.JoinPath("/contents/" + id + "/encryption-percentage")
 	values := encryptionConfigUrl.Query()
 	values.Add("bitrate", bitrate)
 	encryptionConfigUrl.RawQuery = values.Encode()
 	endpoint := encryptionConfigUrl.String()
 
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		rc.Logger.Error().Msg(err.Error())
-		return http.StatusInternalServerError, err
+		return http.StatusInternalServerError, 0, err
 	}
 
 	rc.Logger.Debug().Msgf("requesting content encryption config from %s", endpoint)
 	resp, err := rc.Client.Do(req)
 	if err != nil {
 		rc.Logger.Error().Msg(err.Error())
-		return http.StatusInternalServerError, err
+		return 0, 0, &netError{err}
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -160,28 +319,40 @@ func (rc *RemoteContent) GetEncryptionConfig(id string, bitrate string, v *Conte
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		rc.Logger.Error().Msg(err.Error())
-		return http.StatusInternalServerError, err
+		return http.StatusInternalServerError, 0, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		if err := rc.recorder.record(req, resp.StatusCode, body, nil); err != nil {
+			rc.Logger.Error().Msg(err.Error())
+		}
 		msg := string(body)
 		rc.Logger.Debug().Msgf("%d - %s", resp.StatusCode, msg)
-		return resp.StatusCode, fmt.Errorf("%s", msg)
+		return resp.StatusCode, retryAfter(resp.Header), fmt.Errorf("%s", msg)
+	}
+
+	if len(rc.TrustedKeys) > 0 {
+		if err := verifySignature(rc.TrustedKeys, resp.Header, body); err != nil {
+			rc.Logger.Error().Msg(err.Error())
+			if recErr := rc.recorder.record(req, resp.StatusCode, body, err); recErr != nil {
+				rc.Logger.Error().Msg(recErr.Error())
+			}
+			return http.StatusInternalServerError, 0, err
+		}
 	}
 
 	rc.Logger.Info().Msg("decoding content encryption config...")
-	err = json.Unmarshal(body, &v)
-	if err != nil {
+	decodeErr := json.Unmarshal(body, &v)
+	if err := rc.recorder.record(req, resp.StatusCode, body, decodeErr); err != nil {
 		rc.Logger.Error().Msg(err.Error())
-		return http.StatusInternalServerError, err
+	}
+	if decodeErr != nil {
+		rc.Logger.Error().Msg(decodeErr.Error())
+		return http.StatusInternalServerError, 0, decodeErr
 	}
 	v.RawData = string(body)
 	rc.Logger.Info().Msg("content encryption config successfully decoded")
 	rc.Logger.Debug().Msgf("content encryption config:%+v", *v)
 
-	if rc.CacheEnabled {
-		rc.ContentEncryptionConfigCache[cacheKey] = v
-	}
-
-	return resp.StatusCode, nil
+	return resp.StatusCode, 0, nil
 }
@@ -110,6 +110,14 @@ func TestGetConfig(t *testing.T) {
 			if d.expectedCode != code {
 				t.Errorf("expected code '%d', got '%d'", d.expectedCode, code)
 			}
+			if code == http.StatusOK {
+				if contentConfig.Uuid != "f0121a13-8f2a-4dac-ab07-b49e10aeefcf" {
+					t.Errorf("expected uuid 'f0121a13-8f2a-4dac-ab07-b49e10aeefcf', got '%s'", contentConfig.Uuid)
+				}
+				if contentConfig.ContentName != "Sintel VOD Dash encrypted" {
+					t.Errorf("expected contentName 'Sintel VOD Dash encrypted', got '%s'", contentConfig.ContentName)
+				}
+			}
 		})
 	}
 }
@@ -250,6 +258,14 @@ func TestGetEncryptionConfig(t *testing.T) {
 			if d.expectedCode != code {
 				t.Errorf("expected code '%d', got '%d'", d.expectedCode, code)
 			}
+			if code == http.StatusOK {
+				if contentEncryptionConfig.ChosenFrom != "ENCRYPTION_PERCENTAGE_TITLE" {
+					t.Errorf("expected chosenFrom 'ENCRYPTION_PERCENTAGE_TITLE', got '%s'", contentEncryptionConfig.ChosenFrom)
+				}
+				if len(contentEncryptionConfig.EncryptionPercentagesPerBitrates) != 3 {
+					t.Errorf("expected 3 encryptionPercentagesPerBitrates, got %d", len(contentEncryptionConfig.EncryptionPercentagesPerBitrates))
+				}
+			}
 		})
 	}
 }
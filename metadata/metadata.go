@@ -1,32 +1,197 @@
 package metadata
 
 import (
+	"context"
+	"crypto/ed25519"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
+const (
+	// defaultCacheCapacity bounds the number of entries kept per cache
+	// before the least recently used one is evicted.
+	defaultCacheCapacity = 1000
+
+	// defaultCacheTTL is how long a cached entry is served before it is
+	// treated as a miss and re-fetched.
+	defaultCacheTTL = 5 * time.Minute
+
+	// defaultRetryInitialInterval is the first backoff delay between retry
+	// attempts.
+	defaultRetryInitialInterval = 200 * time.Millisecond
+
+	// defaultRetryMaxInterval caps how large the backoff delay can grow.
+	defaultRetryMaxInterval = 5 * time.Second
+
+	// defaultRetryMaxElapsedTime bounds the total time spent retrying a
+	// single call before giving up.
+	defaultRetryMaxElapsedTime = 30 * time.Second
+
+	// defaultCircuitBreakerFailureThreshold is how many consecutive
+	// failures trip the breaker open.
+	defaultCircuitBreakerFailureThreshold = 5
+
+	// defaultCircuitBreakerCooldown is how long the breaker stays open
+	// before letting a half-open probe through.
+	defaultCircuitBreakerCooldown = 30 * time.Second
+
+	// defaultMaxConcurrentRequests bounds how many upstream requests a
+	// batch call (GetConfigs, GetEncryptionConfigs) issues at once.
+	defaultMaxConcurrentRequests = 10
+)
+
+// Options configures a RemoteContent returned by NewContentManagerWithOptions.
+type Options struct {
+	// CacheEnabled turns on the in-memory LRU cache for both ContentConfig
+	// and ContentEncryptionConfig lookups.
+	CacheEnabled bool
+
+	// CacheCapacity bounds how many entries each cache holds before
+	// evicting the least recently used one. Defaults to defaultCacheCapacity
+	// when <= 0.
+	CacheCapacity int
+
+	// CacheTTL is how long a cached entry remains valid. Defaults to
+	// defaultCacheTTL when <= 0.
+	CacheTTL time.Duration
+
+	// RetryInitialInterval is the first backoff delay between retries.
+	// Defaults to defaultRetryInitialInterval when <= 0.
+	RetryInitialInterval time.Duration
+
+	// RetryMaxInterval caps how large the backoff delay can grow. Defaults
+	// to defaultRetryMaxInterval when <= 0.
+	RetryMaxInterval time.Duration
+
+	// RetryMaxElapsedTime bounds the total time spent retrying a single
+	// call. Defaults to defaultRetryMaxElapsedTime when <= 0.
+	RetryMaxElapsedTime time.Duration
+
+	// CircuitBreakerFailureThreshold is how many consecutive failures trip
+	// an endpoint's breaker open. Defaults to
+	// defaultCircuitBreakerFailureThreshold when <= 0.
+	CircuitBreakerFailureThreshold int
+
+	// CircuitBreakerCooldown is how long an open breaker waits before
+	// letting a half-open probe through. Defaults to
+	// defaultCircuitBreakerCooldown when <= 0.
+	CircuitBreakerCooldown time.Duration
+
+	// TrustedKeys maps key id to public key for verifying the
+	// X-Vividas-Signature header on every response. Keying by id rather
+	// than trusting a single key makes rotation a matter of adding the new
+	// id alongside the old one. Leave nil/empty to disable verification.
+	TrustedKeys map[string]ed25519.PublicKey
+
+	// Metrics receives instrumentation events. Defaults to a no-op
+	// collector; see the metadata/metrics subpackage for a Prometheus
+	// implementation.
+	Metrics Collector
+
+	// MaxConcurrentRequests bounds how many upstream requests a batch call
+	// (GetConfigs, GetEncryptionConfigs) issues at once. Defaults to
+	// defaultMaxConcurrentRequests when <= 0.
+	MaxConcurrentRequests int
+
+	// RecordDir turns on recording mode: every outbound HTTP call writes a
+	// self-contained reproducer file to this directory, for replaying with
+	// curl or loading with metadata/replay. Leave empty to disable.
+	RecordDir string
+}
+
 // NewContentManager returns a new RemoteContent object that
 // implements the ContentManager interface.
 func NewContentManager(serviceUrl string, client *http.Client, cacheEnabled bool) (*RemoteContent, error) {
+	return NewContentManagerWithOptions(serviceUrl, client, Options{CacheEnabled: cacheEnabled})
+}
+
+// NewContentManagerWithOptions returns a new RemoteContent object configured
+// by opts, implementing the ContentManager interface.
+func NewContentManagerWithOptions(serviceUrl string, client *http.Client, opts Options) (*RemoteContent, error) {
 	parsedUrl, err := url.Parse(serviceUrl)
 	if err != nil {
 		return nil, err
 	}
+
+	capacity := opts.CacheCapacity
+	if capacity <= 0 {
+		capacity = defaultCacheCapacity
+	}
+	ttl := opts.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+
+	retryInitialInterval := opts.RetryInitialInterval
+	if retryInitialInterval <= 0 {
+		retryInitialInterval = defaultRetryInitialInterval
+	}
+	retryMaxInterval := opts.RetryMaxInterval
+	if retryMaxInterval <= 0 {
+		retryMaxInterval = defaultRetryMaxInterval
+	}
+	retryMaxElapsedTime := opts.RetryMaxElapsedTime
+	if retryMaxElapsedTime <= 0 {
+		retryMaxElapsedTime = defaultRetryMaxElapsedTime
+	}
+
+	failureThreshold := opts.CircuitBreakerFailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = defaultCircuitBreakerFailureThreshold
+	}
+	cooldown := opts.CircuitBreakerCooldown
+	if cooldown <= 0 {
+		cooldown = defaultCircuitBreakerCooldown
+	}
+
+	metrics := opts.Metrics
+	if metrics == nil {
+		metrics = noopCollector{}
+	}
+
+	maxConcurrentRequests := opts.MaxConcurrentRequests
+	if maxConcurrentRequests <= 0 {
+		maxConcurrentRequests = defaultMaxConcurrentRequests
+	}
+
 	return &RemoteContent{
 		ServiceUrl:                   parsedUrl,
 		Client:                       client,
 		Logger:                       zerolog.New(os.Stdout).With().Timestamp().Logger(),
-		ContentConfigCache:           map[string]*ContentConfig{},
-		ContentEncryptionConfigCache: map[string]*ContentEncryptionConfig{},
-		CacheEnabled:                 cacheEnabled,
+		ContentConfigCache:           newContentCache(capacity, ttl),
+		ContentEncryptionConfigCache: newContentCache(capacity, ttl),
+		CacheEnabled:                 opts.CacheEnabled,
+		RetryInitialInterval:         retryInitialInterval,
+		RetryMaxInterval:             retryMaxInterval,
+		RetryMaxElapsedTime:          retryMaxElapsedTime,
+		configBreaker:                newCircuitBreaker(failureThreshold, cooldown),
+		encryptionConfigBreaker:      newCircuitBreaker(failureThreshold, cooldown),
+		TrustedKeys:                  opts.TrustedKeys,
+		Metrics:                      metrics,
+		configStats:                  newEndpointStatTracker(),
+		encryptionConfigStats:        newEndpointStatTracker(),
+		MaxConcurrentRequests:        maxConcurrentRequests,
+		recorder:                     newRequestRecorder(opts.RecordDir),
 	}, nil
 }
 
 // ContentManager consisting of core methods to manage content metadata.
 type ContentManager interface {
 	GetConfig(string, *ContentConfig) (int, error)
-	GetEncryptionConfig(string, string, *ContentEncryptionConfig, *string) (int, error)
+	GetConfigCtx(context.Context, string, *ContentConfig) (int, error)
+	GetEncryptionConfig(string, string, *ContentEncryptionConfig) (int, error)
+	GetEncryptionConfigCtx(context.Context, string, string, *ContentEncryptionConfig) (int, error)
+	GetConfigs([]string) (map[string]ContentConfigResult, error)
+	GetConfigsCtx(context.Context, []string) (map[string]ContentConfigResult, error)
+	GetEncryptionConfigs([]EncryptionRequest) (map[string]ContentEncryptionConfigResult, error)
+	GetEncryptionConfigsCtx(context.Context, []EncryptionRequest) (map[string]ContentEncryptionConfigResult, error)
 }
+
+// var _ ContentManager = (*RemoteContent)(nil) ensures RemoteContent keeps
+// satisfying ContentManager as the interface grows; a drift here fails the
+// build instead of surfacing as a runtime type assertion panic.
+var _ ContentManager = (*RemoteContent)(nil)
@@ -0,0 +1,46 @@
+package metadata
+
+import "time"
+
+// Collector receives instrumentation events from a RemoteContent. Calls must
+// be safe for concurrent use. See the metadata/metrics subpackage for a
+// ready-to-register Prometheus implementation.
+type Collector interface {
+	// ObserveRequest records the outcome of a single upstream HTTP round
+	// trip for endpoint (e.g. "config" or "encryption_config").
+	ObserveRequest(endpoint string, code int, dur time.Duration)
+
+	// IncCacheHit/IncCacheMiss count lookups against the cache for kind
+	// ("config" or "encryption_config").
+	IncCacheHit(kind string)
+	IncCacheMiss(kind string)
+
+	// IncRetry counts a retried attempt against endpoint.
+	IncRetry(endpoint string)
+
+	// IncCircuitOpen counts a call short circuited by an open breaker for
+	// endpoint.
+	IncCircuitOpen(endpoint string)
+
+	// IncInFlight/DecInFlight bracket an in-progress upstream call to
+	// endpoint.
+	IncInFlight(endpoint string)
+	DecInFlight(endpoint string)
+
+	// SetCacheSize reports the current number of entries held by the
+	// cache for kind.
+	SetCacheSize(kind string, size int)
+}
+
+// noopCollector is the default Collector, used when no Options.Metrics is
+// configured so call sites never have to nil-check rc.Metrics.
+type noopCollector struct{}
+
+func (noopCollector) ObserveRequest(string, int, time.Duration) {}
+func (noopCollector) IncCacheHit(string)                        {}
+func (noopCollector) IncCacheMiss(string)                       {}
+func (noopCollector) IncRetry(string)                           {}
+func (noopCollector) IncCircuitOpen(string)                     {}
+func (noopCollector) IncInFlight(string)                        {}
+func (noopCollector) DecInFlight(string)                        {}
+func (noopCollector) SetCacheSize(string, int)                  {}
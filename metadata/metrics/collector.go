@@ -0,0 +1,111 @@
+// Package metrics provides a Prometheus-backed implementation of
+// metadata.Collector for instrumenting a metadata.RemoteContent.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wz-rjuarez/vividas-go/metadata"
+)
+
+const namespace = "vividas_metadata_client"
+
+// Collector is a metadata.Collector that records activity as Prometheus
+// metrics. It also implements prometheus.Collector, so it can be registered
+// directly with a prometheus.Registerer.
+type Collector struct {
+	requestDuration *prometheus.HistogramVec
+	cacheHits       *prometheus.CounterVec
+	cacheMisses     *prometheus.CounterVec
+	retries         *prometheus.CounterVec
+	circuitOpens    *prometheus.CounterVec
+	inFlight        *prometheus.GaugeVec
+	cacheSize       *prometheus.GaugeVec
+}
+
+var _ metadata.Collector = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+
+// New returns a Collector ready to be registered with a Prometheus registry
+// and passed as Options.Metrics.
+func New() *Collector {
+	return &Collector{
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of upstream requests, by endpoint and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "status"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_hits_total",
+			Help:      "Cache lookups that were served from cache, by kind.",
+		}, []string{"kind"}),
+		cacheMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_misses_total",
+			Help:      "Cache lookups that required an upstream fetch, by kind.",
+		}, []string{"kind"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Retried upstream requests, by endpoint.",
+		}, []string{"endpoint"}),
+		circuitOpens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "circuit_open_total",
+			Help:      "Requests short circuited by an open breaker, by endpoint.",
+		}, []string{"endpoint"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "in_flight_requests",
+			Help:      "Upstream requests currently in flight, by endpoint.",
+		}, []string{"endpoint"}),
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "cache_entries",
+			Help:      "Entries currently held by the cache, by kind.",
+		}, []string{"kind"}),
+	}
+}
+
+func (c *Collector) ObserveRequest(endpoint string, code int, dur time.Duration) {
+	c.requestDuration.WithLabelValues(endpoint, strconv.Itoa(code)).Observe(dur.Seconds())
+}
+
+func (c *Collector) IncCacheHit(kind string)  { c.cacheHits.WithLabelValues(kind).Inc() }
+func (c *Collector) IncCacheMiss(kind string) { c.cacheMisses.WithLabelValues(kind).Inc() }
+
+func (c *Collector) IncRetry(endpoint string)       { c.retries.WithLabelValues(endpoint).Inc() }
+func (c *Collector) IncCircuitOpen(endpoint string) { c.circuitOpens.WithLabelValues(endpoint).Inc() }
+
+func (c *Collector) IncInFlight(endpoint string) { c.inFlight.WithLabelValues(endpoint).Inc() }
+func (c *Collector) DecInFlight(endpoint string) { c.inFlight.WithLabelValues(endpoint).Dec() }
+
+func (c *Collector) SetCacheSize(kind string, size int) {
+	c.cacheSize.WithLabelValues(kind).Set(float64(size))
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requestDuration.Describe(ch)
+	c.cacheHits.Describe(ch)
+	c.cacheMisses.Describe(ch)
+	c.retries.Describe(ch)
+	c.circuitOpens.Describe(ch)
+	c.inFlight.Describe(ch)
+	c.cacheSize.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requestDuration.Collect(ch)
+	c.cacheHits.Collect(ch)
+	c.cacheMisses.Collect(ch)
+	c.retries.Collect(ch)
+	c.circuitOpens.Collect(ch)
+	c.inFlight.Collect(ch)
+	c.cacheSize.Collect(ch)
+}
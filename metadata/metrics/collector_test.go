@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectorRegistersAndEmitsMetrics(t *testing.T) {
+	c := New()
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatalf("failed to register collector: %v", err)
+	}
+
+	c.ObserveRequest("config", 200, 10*time.Millisecond)
+	c.IncCacheHit("config")
+	c.IncCacheMiss("config")
+	c.IncRetry("config")
+	c.IncCircuitOpen("config")
+	c.IncInFlight("config")
+	c.SetCacheSize("config", 5)
+
+	wantNames := []string{
+		namespace + "_request_duration_seconds",
+		namespace + "_cache_hits_total",
+		namespace + "_cache_misses_total",
+		namespace + "_retries_total",
+		namespace + "_circuit_open_total",
+		namespace + "_in_flight_requests",
+		namespace + "_cache_entries",
+	}
+	for _, name := range wantNames {
+		if count := testutil.CollectAndCount(c, name); count != 1 {
+			t.Errorf("expected metric %q to be emitted once, got %d", name, count)
+		}
+	}
+}
+
+func TestCollectorInFlightTracksIncrementsAndDecrements(t *testing.T) {
+	c := New()
+
+	c.IncInFlight("config")
+	c.IncInFlight("config")
+	c.DecInFlight("config")
+
+	if got := testutil.ToFloat64(c.inFlight.WithLabelValues("config")); got != 1 {
+		t.Errorf("expected 1 in-flight request, got %v", got)
+	}
+}
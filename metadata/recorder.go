@@ -0,0 +1,74 @@
+package metadata
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// requestRecorder writes a self-contained reproducer file for every
+// outbound HTTP call RemoteContent makes, when Options.RecordDir was
+// set. Each file captures the request line, headers, query, a timestamp,
+// the response status and body, and any decode error, in a plain key:value
+// format that's easy to replay with curl or load as a metadata/replay
+// fixture.
+type requestRecorder struct {
+	dir string
+	seq uint64
+}
+
+func newRequestRecorder(dir string) *requestRecorder {
+	return &requestRecorder{dir: dir}
+}
+
+// record writes one reproducer file for a single request/response pair.
+// It is a no-op when the recorder has no directory configured. decodeErr
+// may be nil.
+func (r *requestRecorder) record(req *http.Request, status int, body []byte, decodeErr error) error {
+	if r.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+
+	n := atomic.AddUint64(&r.seq, 1)
+	name := fmt.Sprintf("%d-%04d-%s.txt", time.Now().UnixNano(), n, sanitizeForFilename(req.URL.Path))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "METHOD: %s\n", req.Method)
+	fmt.Fprintf(&b, "PATH: %s\n", req.URL.Path)
+	fmt.Fprintf(&b, "QUERY: %s\n", req.URL.RawQuery)
+	for header, values := range req.Header {
+		for _, v := range values {
+			fmt.Fprintf(&b, "HEADER: %s: %s\n", header, v)
+		}
+	}
+	fmt.Fprintf(&b, "TIMESTAMP: %s\n", time.Now().UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "STATUS: %d\n", status)
+	decodeErrStr := ""
+	if decodeErr != nil {
+		decodeErrStr = decodeErr.Error()
+	}
+	fmt.Fprintf(&b, "DECODE_ERROR: %s\n", decodeErrStr)
+	b.WriteString("BODY:\n")
+	b.Write(body)
+	b.WriteString("\n")
+
+	return os.WriteFile(filepath.Join(r.dir, name), []byte(b.String()), 0o644)
+}
+
+// sanitizeForFilename turns a URL path into something safe to embed in a
+// file name.
+func sanitizeForFilename(p string) string {
+	p = strings.Trim(p, "/")
+	p = strings.ReplaceAll(p, "/", "_")
+	if p == "" {
+		return "root"
+	}
+	return p
+}
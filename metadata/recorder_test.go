@@ -0,0 +1,97 @@
+package metadata
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequestRecorderWritesReproducer(t *testing.T) {
+	dir := t.TempDir()
+	r := newRequestRecorder(dir)
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/contents/abc?bitrate=1080", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if err := r.record(req, http.StatusOK, []byte(`{"uuid":"abc"}`), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 reproducer file, got %d", len(entries))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	for _, want := range []string{
+		"METHOD: GET",
+		"PATH: /contents/abc",
+		"QUERY: bitrate=1080",
+		"HEADER: Accept: application/json",
+		"STATUS: 200",
+		"DECODE_ERROR: \n",
+		"BODY:\n{\"uuid\":\"abc\"}",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected reproducer to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestRequestRecorderNoopWithoutDir(t *testing.T) {
+	r := newRequestRecorder("")
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/contents/abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.record(req, http.StatusOK, []byte("{}"), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestRecorderRecordsDecodeErrors(t *testing.T) {
+	dir := t.TempDir()
+	r := newRequestRecorder(dir)
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/contents/abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decodeErr := &jsonSyntaxErrorStub{}
+	if err := r.record(req, http.StatusOK, []byte("not json"), decodeErr); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "DECODE_ERROR: invalid character") {
+		t.Errorf("expected decode error to be recorded, got:\n%s", string(data))
+	}
+}
+
+type jsonSyntaxErrorStub struct{}
+
+func (e *jsonSyntaxErrorStub) Error() string {
+	return "invalid character 'n' looking for beginning of value"
+}
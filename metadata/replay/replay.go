@@ -0,0 +1,113 @@
+// Package replay stands up an httptest.Server that replays HTTP responses
+// recorded by metadata.RemoteContent's recording mode (Options.RecordDir),
+// so bugs reported from production can be reproduced verbatim in unit
+// tests.
+package replay
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Response is a single recorded HTTP response, keyed by the request's URL
+// path and raw query.
+type Response struct {
+	Status int
+	Body   []byte
+}
+
+// Server stands up an httptest.Server that replays the responses recorded
+// in dir. Requests that don't match a recorded path and query fail with a
+// 404 and a descriptive body.
+func Server(dir string) (*httptest.Server, error) {
+	responses, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		key := requestKey(req.URL.Path, req.URL.RawQuery)
+		resp, ok := responses[key]
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(rw, "replay: no recorded response for %s", key)
+			return
+		}
+		rw.WriteHeader(resp.Status)
+		rw.Write(resp.Body)
+	})), nil
+}
+
+// Load parses every reproducer file in dir and returns the recorded
+// responses keyed by URL path and raw query.
+func Load(dir string) (map[string]Response, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(map[string]Response, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		path, query, resp, err := parseReproducer(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("replay: %s: %w", entry.Name(), err)
+		}
+		responses[requestKey(path, query)] = resp
+	}
+	return responses, nil
+}
+
+// parseReproducer parses a single reproducer file written by
+// metadata.RemoteContent's recording mode.
+func parseReproducer(data string) (path, query string, resp Response, err error) {
+	lines := strings.Split(data, "\n")
+
+	var status int
+	var statusSeen bool
+	bodyIdx := -1
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "PATH: "):
+			path = strings.TrimPrefix(line, "PATH: ")
+		case strings.HasPrefix(line, "QUERY: "):
+			query = strings.TrimPrefix(line, "QUERY: ")
+		case strings.HasPrefix(line, "STATUS: "):
+			status, err = strconv.Atoi(strings.TrimPrefix(line, "STATUS: "))
+			if err != nil {
+				return "", "", Response{}, fmt.Errorf("malformed STATUS line: %w", err)
+			}
+			statusSeen = true
+		case line == "BODY:":
+			bodyIdx = i + 1
+		}
+		if bodyIdx != -1 {
+			break
+		}
+	}
+
+	if !statusSeen {
+		return "", "", Response{}, fmt.Errorf("missing STATUS line")
+	}
+	if bodyIdx == -1 {
+		return "", "", Response{}, fmt.Errorf("missing BODY section")
+	}
+
+	body := strings.TrimSuffix(strings.Join(lines[bodyIdx:], "\n"), "\n")
+	return path, query, Response{Status: status, Body: []byte(body)}, nil
+}
+
+func requestKey(path, query string) string {
+	return path + "?" + query
+}
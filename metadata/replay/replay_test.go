@@ -0,0 +1,73 @@
+package replay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wz-rjuarez/vividas-go/metadata"
+)
+
+func TestServerReplaysRecordedResponses(t *testing.T) {
+	dir := t.TempDir()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{
+			"uuid":"f0121a13-8f2a-4dac-ab07-b49e10aeefcf",
+			"contentName":"Sintel VOD Dash encrypted"
+			}`))
+	}))
+	defer upstream.Close()
+
+	contentManager, err := metadata.NewContentManagerWithOptions(upstream.URL, upstream.Client(), metadata.Options{
+		RecordDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg metadata.ContentConfig
+	if _, err := contentManager.GetConfig("f0121a13-8f2a-4dac-ab07-b49e10aeefcf", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("expected at least one reproducer file to be written")
+	}
+
+	server, err := Server(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	replayedManager, err := metadata.NewContentManager(server.URL, server.Client(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var replayed metadata.ContentConfig
+	code, err := replayedManager.GetConfig("f0121a13-8f2a-4dac-ab07-b49e10aeefcf", &replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != http.StatusOK {
+		t.Errorf("expected 200, got %d", code)
+	}
+	if replayed.Uuid != cfg.Uuid || replayed.ContentName != cfg.ContentName {
+		t.Errorf("expected replayed config to match the original, got %+v", replayed)
+	}
+}
+
+func TestLoadReturnsErrorForMissingDir(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "does-not-exist")); err == nil {
+		t.Fatal("expected an error loading a non-existent directory")
+	}
+}
@@ -0,0 +1,151 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// netError marks an error as a transport-level failure (the request never
+// reached the upstream, as opposed to the upstream answering with an error
+// status), so retry logic can tell it apart from e.g. a decode error.
+type netError struct {
+	err error
+}
+
+func (e *netError) Error() string { return e.err.Error() }
+func (e *netError) Unwrap() error { return e.err }
+
+// retryableStatus reports whether code is one of the upstream statuses worth
+// retrying an idempotent GET for.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter parses a Retry-After header expressed as a number of seconds.
+// HTTP-date values aren't used by the encryption-metadata service, so only
+// the delta-seconds form is supported; anything else is ignored.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// retryAfterBackOff wraps a backoff.BackOff and, when set, serves a single
+// externally supplied interval (derived from a Retry-After header) before
+// falling back to the wrapped policy.
+type retryAfterBackOff struct {
+	inner    backoff.BackOff
+	override time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	if b.override > 0 {
+		d := b.override
+		b.override = 0
+		return d
+	}
+	return b.inner.NextBackOff()
+}
+
+func (b *retryAfterBackOff) Reset() { b.inner.Reset() }
+
+// fetchFunc performs a single attempt of an upstream call, reporting the
+// HTTP status code it observed (0 if the request never reached the
+// upstream), any Retry-After delay the upstream asked for, and an error.
+type fetchFunc func() (code int, after time.Duration, err error)
+
+// withRetryAndBreaker runs fn, retrying idempotent GETs on network errors and
+// on 502/503/504 responses per rc's backoff settings, honoring Retry-After,
+// and short circuiting through breaker when it is open. endpoint labels the
+// Metrics/Stats events this call emits.
+func (rc *RemoteContent) withRetryAndBreaker(ctx context.Context, endpoint string, breaker *circuitBreaker, stats *endpointStatTracker, fn fetchFunc) (int, error) {
+	if !breaker.allow() {
+		rc.Logger.Warn().Msg("circuit breaker open, short circuiting request")
+		rc.Metrics.IncCircuitOpen(endpoint)
+		stats.recordCircuitOpen()
+		return http.StatusServiceUnavailable, ErrCircuitOpen
+	}
+
+	bo := &retryAfterBackOff{inner: rc.newExponentialBackOff()}
+
+	rc.Metrics.IncInFlight(endpoint)
+	defer rc.Metrics.DecInFlight(endpoint)
+
+	var (
+		code            int
+		lastErr         error
+		upstreamFailure bool
+	)
+	err := backoff.RetryNotify(func() error {
+		start := time.Now()
+		var (
+			after time.Duration
+			opErr error
+		)
+		code, after, opErr = fn()
+		rc.Metrics.ObserveRequest(endpoint, code, time.Since(start))
+		stats.recordServed(code)
+		if opErr == nil {
+			return nil
+		}
+
+		lastErr = opErr
+		_, isNetErr := opErr.(*netError)
+		upstreamFailure = isNetErr || retryableStatus(code)
+		if !upstreamFailure {
+			// A client error (4xx) says nothing about upstream health, so
+			// it isn't retried and doesn't count against the breaker.
+			return backoff.Permanent(opErr)
+		}
+
+		if after > 0 {
+			bo.override = after
+		}
+		return opErr
+	}, backoff.WithContext(bo, ctx), func(opErr error, _ time.Duration) {
+		// Only called when another attempt will follow.
+		rc.Metrics.IncRetry(endpoint)
+		stats.recordRetry()
+		rc.Logger.Debug().Msgf("retrying request after failure: %s", opErr.Error())
+	})
+
+	if err != nil {
+		if upstreamFailure {
+			breaker.recordFailure()
+		}
+		if code == 0 {
+			code = http.StatusInternalServerError
+		}
+		if lastErr != nil {
+			return code, lastErr
+		}
+		return code, err
+	}
+
+	breaker.recordSuccess()
+	return code, nil
+}
+
+func (rc *RemoteContent) newExponentialBackOff() *backoff.ExponentialBackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = rc.RetryInitialInterval
+	bo.MaxInterval = rc.RetryMaxInterval
+	bo.MaxElapsedTime = rc.RetryMaxElapsedTime
+	bo.Reset()
+	return bo
+}
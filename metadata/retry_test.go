@@ -0,0 +1,129 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestRemoteContent() *RemoteContent {
+	return &RemoteContent{
+		RetryInitialInterval:    time.Millisecond,
+		RetryMaxInterval:        5 * time.Millisecond,
+		RetryMaxElapsedTime:     200 * time.Millisecond,
+		configBreaker:           newCircuitBreaker(10, time.Minute),
+		encryptionConfigBreaker: newCircuitBreaker(10, time.Minute),
+		Metrics:                 noopCollector{},
+		configStats:             newEndpointStatTracker(),
+		encryptionConfigStats:   newEndpointStatTracker(),
+		MaxConcurrentRequests:   defaultMaxConcurrentRequests,
+		recorder:                newRequestRecorder(""),
+	}
+}
+
+func TestWithRetryAndBreakerRetriesNetworkErrors(t *testing.T) {
+	rc := newTestRemoteContent()
+
+	attempts := 0
+	code, err := rc.withRetryAndBreaker(context.Background(), endpointConfig, rc.configBreaker, rc.configStats, func() (int, time.Duration, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, 0, &netError{errors.New("connection reset")}
+		}
+		return http.StatusOK, 0, nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected eventual success, got err %v", err)
+	}
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestWithRetryAndBreakerRetries503(t *testing.T) {
+	rc := newTestRemoteContent()
+
+	attempts := 0
+	code, err := rc.withRetryAndBreaker(context.Background(), endpointConfig, rc.configBreaker, rc.configStats, func() (int, time.Duration, error) {
+		attempts++
+		if attempts < 2 {
+			return http.StatusServiceUnavailable, 0, errors.New("unavailable")
+		}
+		return http.StatusOK, 0, nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected eventual success, got err %v", err)
+	}
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestWithRetryAndBreakerDoesNotRetryClientErrors(t *testing.T) {
+	rc := newTestRemoteContent()
+
+	attempts := 0
+	code, err := rc.withRetryAndBreaker(context.Background(), endpointConfig, rc.configBreaker, rc.configStats, func() (int, time.Duration, error) {
+		attempts++
+		return http.StatusBadRequest, 0, errors.New("bad request")
+	})
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", code)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected exactly 1 attempt for a non-retryable error, got %d", attempts)
+	}
+	if !rc.configBreaker.allow() {
+		t.Fatal("client errors should not trip the breaker")
+	}
+}
+
+func TestWithRetryAndBreakerShortCircuitsWhenOpen(t *testing.T) {
+	rc := newTestRemoteContent()
+	rc.configBreaker = newCircuitBreaker(1, time.Hour)
+
+	_, _ = rc.withRetryAndBreaker(context.Background(), endpointConfig, rc.configBreaker, rc.configStats, func() (int, time.Duration, error) {
+		return 0, 0, &netError{errors.New("down")}
+	})
+
+	attempts := 0
+	_, err := rc.withRetryAndBreaker(context.Background(), endpointConfig, rc.configBreaker, rc.configStats, func() (int, time.Duration, error) {
+		attempts++
+		return http.StatusOK, 0, nil
+	})
+
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if attempts != 0 {
+		t.Fatal("expected the breaker to short circuit before calling fn")
+	}
+}
+
+func TestRetryAfterParsesDeltaSeconds(t *testing.T) {
+	h := http.Header{}
+	h.Set("Retry-After", "2")
+
+	if got := retryAfter(h); got != 2*time.Second {
+		t.Fatalf("expected 2s, got %v", got)
+	}
+
+	h.Set("Retry-After", "not-a-number")
+	if got := retryAfter(h); got != 0 {
+		t.Fatalf("expected 0 for an unparsable header, got %v", got)
+	}
+}
@@ -0,0 +1,50 @@
+package metadata
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// signatureHeader carries a response signature as "keyid:base64(sig)", the
+// signature being computed over the raw, unparsed response body.
+const signatureHeader = "X-Vividas-Signature"
+
+// ErrUntrustedResponse is returned when signature verification is enabled
+// and a response is missing a signature, names an unknown key id, or fails
+// to verify against the raw body.
+var ErrUntrustedResponse = errors.New("metadata: response signature is untrusted")
+
+// verifySignature checks header against body using trustedKeys, a
+// key id -> public key lookup that makes key rotation a matter of adding a
+// new id rather than replacing the one key callers trust.
+func verifySignature(trustedKeys map[string]ed25519.PublicKey, header http.Header, body []byte) error {
+	raw := header.Get(signatureHeader)
+	if raw == "" {
+		return fmt.Errorf("%w: missing %s header", ErrUntrustedResponse, signatureHeader)
+	}
+
+	keyID, encodedSig, ok := strings.Cut(raw, ":")
+	if !ok {
+		return fmt.Errorf("%w: malformed %s header", ErrUntrustedResponse, signatureHeader)
+	}
+
+	key, ok := trustedKeys[keyID]
+	if !ok {
+		return fmt.Errorf("%w: unknown key id %q", ErrUntrustedResponse, keyID)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature: %s", ErrUntrustedResponse, err)
+	}
+
+	if !ed25519.Verify(key, body, sig) {
+		return fmt.Errorf("%w: signature does not match body", ErrUntrustedResponse)
+	}
+
+	return nil
+}
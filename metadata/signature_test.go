@@ -0,0 +1,234 @@
+package metadata
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func signedConfigServer(t *testing.T, key ed25519.PrivateKey, keyID string) *httptest.Server {
+	t.Helper()
+
+	body := []byte(`{
+		"uuid":"f0121a13-8f2a-4dac-ab07-b49e10aeefcf",
+		"contentName":"Sintel VOD Dash encrypted",
+		"status":"CREATED"
+		}`)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/contents/{contentId}", func(rw http.ResponseWriter, req *http.Request) {
+		sig := ed25519.Sign(key, body)
+		rw.Header().Set(signatureHeader, fmt.Sprintf("%s:%s", keyID, base64.StdEncoding.EncodeToString(sig)))
+		rw.WriteHeader(http.StatusOK)
+		rw.Write(body)
+	})
+	return httptest.NewServer(router)
+}
+
+func TestGetConfigVerifiesTrustedSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := signedConfigServer(t, priv, "key-1")
+	defer ts.Close()
+
+	rc, err := NewContentManagerWithOptions(ts.URL, ts.Client(), Options{
+		TrustedKeys: map[string]ed25519.PublicKey{"key-1": pub},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg ContentConfig
+	code, err := rc.GetConfig("f0121a13-8f2a-4dac-ab07-b49e10aeefcf", &cfg)
+	if err != nil {
+		t.Fatalf("expected verified response to be accepted, got err %v", err)
+	}
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if cfg.RawData == "" {
+		t.Fatal("expected RawData to be populated with the raw signed body")
+	}
+}
+
+func TestGetConfigRejectsUnknownKeyID(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = pub
+
+	ts := signedConfigServer(t, priv, "key-unknown")
+	defer ts.Close()
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := NewContentManagerWithOptions(ts.URL, ts.Client(), Options{
+		TrustedKeys: map[string]ed25519.PublicKey{"key-1": otherPub},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg ContentConfig
+	code, err := rc.GetConfig("f0121a13-8f2a-4dac-ab07-b49e10aeefcf", &cfg)
+	if !errors.Is(err, ErrUntrustedResponse) {
+		t.Fatalf("expected ErrUntrustedResponse, got %v", err)
+	}
+	if code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", code)
+	}
+}
+
+func TestGetConfigRejectsTamperedBody(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/contents/{contentId}", func(rw http.ResponseWriter, req *http.Request) {
+		signedBody := []byte(`{"uuid":"original"}`)
+		sig := ed25519.Sign(priv, signedBody)
+		rw.Header().Set(signatureHeader, fmt.Sprintf("key-1:%s", base64.StdEncoding.EncodeToString(sig)))
+		rw.WriteHeader(http.StatusOK)
+		// Respond with a different body than the one that was signed.
+		rw.Write([]byte(`{"uuid":"tampered"}`))
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	rc, err := NewContentManagerWithOptions(ts.URL, ts.Client(), Options{
+		TrustedKeys: map[string]ed25519.PublicKey{"key-1": pub},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg ContentConfig
+	code, err := rc.GetConfig("f0121a13-8f2a-4dac-ab07-b49e10aeefcf", &cfg)
+	if !errors.Is(err, ErrUntrustedResponse) {
+		t.Fatalf("expected ErrUntrustedResponse, got %v", err)
+	}
+	if code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", code)
+	}
+}
+
+func TestGetConfigRecordsTamperedBody(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/contents/{contentId}", func(rw http.ResponseWriter, req *http.Request) {
+		signedBody := []byte(`{"uuid":"original"}`)
+		sig := ed25519.Sign(priv, signedBody)
+		rw.Header().Set(signatureHeader, fmt.Sprintf("key-1:%s", base64.StdEncoding.EncodeToString(sig)))
+		rw.WriteHeader(http.StatusOK)
+		// Respond with a different body than the one that was signed.
+		rw.Write([]byte(`{"uuid":"tampered"}`))
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	dir := t.TempDir()
+	rc, err := NewContentManagerWithOptions(ts.URL, ts.Client(), Options{
+		TrustedKeys: map[string]ed25519.PublicKey{"key-1": pub},
+		RecordDir:   dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg ContentConfig
+	if _, err := rc.GetConfig("f0121a13-8f2a-4dac-ab07-b49e10aeefcf", &cfg); !errors.Is(err, ErrUntrustedResponse) {
+		t.Fatalf("expected ErrUntrustedResponse, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected a reproducer to be recorded for the untrusted response, got %d files", len(entries))
+	}
+}
+
+func TestGetConfigRejectsMissingSignature(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/contents/{contentId}", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"uuid":"f0121a13-8f2a-4dac-ab07-b49e10aeefcf"}`))
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	rc, err := NewContentManagerWithOptions(ts.URL, ts.Client(), Options{
+		TrustedKeys: map[string]ed25519.PublicKey{"key-1": pub},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg ContentConfig
+	code, err := rc.GetConfig("f0121a13-8f2a-4dac-ab07-b49e10aeefcf", &cfg)
+	if !errors.Is(err, ErrUntrustedResponse) {
+		t.Fatalf("expected ErrUntrustedResponse, got %v", err)
+	}
+	if code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", code)
+	}
+}
+
+func TestVerifySignatureRotatesKeys(t *testing.T) {
+	oldPub, oldPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPub, newPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trustedKeys := map[string]ed25519.PublicKey{"old": oldPub, "new": newPub}
+	body := []byte(`{"uuid":"f0121a13"}`)
+
+	for _, tc := range []struct {
+		name string
+		key  ed25519.PrivateKey
+		id   string
+	}{
+		{"signed by the retiring key", oldPriv, "old"},
+		{"signed by the newly rotated-in key", newPriv, "new"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			sig := ed25519.Sign(tc.key, body)
+			h.Set(signatureHeader, fmt.Sprintf("%s:%s", tc.id, base64.StdEncoding.EncodeToString(sig)))
+
+			if err := verifySignature(trustedKeys, h, body); err != nil {
+				t.Fatalf("expected signature to verify, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package metadata
+
+import "sync"
+
+// CacheStats is a point-in-time snapshot of one cache's hit/miss counts and
+// size.
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Size   int    `json:"size"`
+}
+
+// EndpointStats is a point-in-time snapshot of the calls served for one
+// endpoint, including the distribution of HTTP status codes observed.
+type EndpointStats struct {
+	Served       uint64         `json:"served"`
+	Retries      uint64         `json:"retries"`
+	CircuitOpens uint64         `json:"circuitOpens"`
+	StatusCodes  map[int]uint64 `json:"statusCodes"`
+}
+
+// Stats is a JSON-marshalable snapshot of a RemoteContent's activity, for
+// callers who don't run Prometheus.
+type Stats struct {
+	Config                EndpointStats `json:"config"`
+	EncryptionConfig      EndpointStats `json:"encryptionConfig"`
+	ConfigCache           CacheStats    `json:"configCache"`
+	EncryptionConfigCache CacheStats    `json:"encryptionConfigCache"`
+}
+
+// endpointStatTracker accumulates the counters behind one EndpointStats
+// snapshot. It is safe for concurrent use.
+type endpointStatTracker struct {
+	mu           sync.Mutex
+	served       uint64
+	retries      uint64
+	circuitOpens uint64
+	cacheHits    uint64
+	cacheMisses  uint64
+	statusCodes  map[int]uint64
+}
+
+func newEndpointStatTracker() *endpointStatTracker {
+	return &endpointStatTracker{statusCodes: make(map[int]uint64)}
+}
+
+func (s *endpointStatTracker) recordServed(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.served++
+	s.statusCodes[code]++
+}
+
+func (s *endpointStatTracker) recordRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+}
+
+func (s *endpointStatTracker) recordCircuitOpen() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.circuitOpens++
+}
+
+func (s *endpointStatTracker) recordCacheHit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cacheHits++
+}
+
+func (s *endpointStatTracker) recordCacheMiss() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cacheMisses++
+}
+
+func (s *endpointStatTracker) snapshot() EndpointStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	codes := make(map[int]uint64, len(s.statusCodes))
+	for code, count := range s.statusCodes {
+		codes[code] = count
+	}
+	return EndpointStats{
+		Served:       s.served,
+		Retries:      s.retries,
+		CircuitOpens: s.circuitOpens,
+		StatusCodes:  codes,
+	}
+}
+
+func (s *endpointStatTracker) cacheSnapshot(size int) CacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return CacheStats{Hits: s.cacheHits, Misses: s.cacheMisses, Size: size}
+}
+
+// Stats returns a snapshot of rc's activity since it was created.
+func (rc *RemoteContent) Stats() Stats {
+	return Stats{
+		Config:                rc.configStats.snapshot(),
+		EncryptionConfig:      rc.encryptionConfigStats.snapshot(),
+		ConfigCache:           rc.configStats.cacheSnapshot(rc.ContentConfigCache.len()),
+		EncryptionConfigCache: rc.encryptionConfigStats.cacheSnapshot(rc.ContentEncryptionConfigCache.len()),
+	}
+}
@@ -0,0 +1,74 @@
+package metadata
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEndpointStatTrackerSnapshot(t *testing.T) {
+	s := newEndpointStatTracker()
+
+	s.recordServed(http.StatusOK)
+	s.recordServed(http.StatusOK)
+	s.recordServed(http.StatusServiceUnavailable)
+	s.recordRetry()
+	s.recordCircuitOpen()
+	s.recordCacheHit()
+	s.recordCacheHit()
+	s.recordCacheMiss()
+
+	snap := s.snapshot()
+	if snap.Served != 3 {
+		t.Errorf("expected 3 served, got %d", snap.Served)
+	}
+	if snap.Retries != 1 {
+		t.Errorf("expected 1 retry, got %d", snap.Retries)
+	}
+	if snap.CircuitOpens != 1 {
+		t.Errorf("expected 1 circuit open, got %d", snap.CircuitOpens)
+	}
+	if snap.StatusCodes[http.StatusOK] != 2 {
+		t.Errorf("expected 2 OK responses, got %d", snap.StatusCodes[http.StatusOK])
+	}
+	if snap.StatusCodes[http.StatusServiceUnavailable] != 1 {
+		t.Errorf("expected 1 503 response, got %d", snap.StatusCodes[http.StatusServiceUnavailable])
+	}
+
+	cacheSnap := s.cacheSnapshot(5)
+	if cacheSnap.Hits != 2 || cacheSnap.Misses != 1 || cacheSnap.Size != 5 {
+		t.Errorf("unexpected cache snapshot: %+v", cacheSnap)
+	}
+}
+
+func TestEndpointStatTrackerSnapshotIsIndependentCopy(t *testing.T) {
+	s := newEndpointStatTracker()
+	s.recordServed(http.StatusOK)
+
+	snap := s.snapshot()
+	snap.StatusCodes[http.StatusOK] = 100
+
+	if s.statusCodes[http.StatusOK] != 1 {
+		t.Error("mutating a snapshot's status codes must not affect the tracker")
+	}
+}
+
+func TestRemoteContentStats(t *testing.T) {
+	rc := newTestRemoteContent()
+	rc.ContentConfigCache = newContentCache(10, defaultCacheTTL)
+	rc.ContentEncryptionConfigCache = newContentCache(10, defaultCacheTTL)
+
+	rc.configStats.recordServed(http.StatusOK)
+	rc.configStats.recordCacheHit()
+	rc.ContentConfigCache.set("some-id", &ContentConfig{})
+
+	stats := rc.Stats()
+	if stats.Config.Served != 1 {
+		t.Errorf("expected 1 served config request, got %d", stats.Config.Served)
+	}
+	if stats.ConfigCache.Hits != 1 {
+		t.Errorf("expected 1 config cache hit, got %d", stats.ConfigCache.Hits)
+	}
+	if stats.ConfigCache.Size != 1 {
+		t.Errorf("expected config cache size 1, got %d", stats.ConfigCache.Size)
+	}
+}